@@ -0,0 +1,48 @@
+// Package apierror defines the structured error type returned by every
+// API handler, rendered by the error middleware into a consistent JSON
+// envelope.
+package apierror
+
+import "net/http"
+
+// APIError is the canonical error shape surfaced to API clients.
+type APIError struct {
+	Code       string `json:"code"`
+	HTTPStatus int    `json:"-"`
+	Message    string `json:"message"`
+	Details    any    `json:"details,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// With returns a copy of the error carrying the given details, leaving
+// the shared predefined value untouched.
+func (e *APIError) With(details any) *APIError {
+	clone := *e
+	clone.Details = details
+	return &clone
+}
+
+// WithMessage returns a copy of the error with a more specific message,
+// leaving the shared predefined value untouched.
+func (e *APIError) WithMessage(message string) *APIError {
+	clone := *e
+	clone.Message = message
+	return &clone
+}
+
+// Predefined errors. Handlers should raise one of these (optionally via
+// With/WithMessage) rather than constructing gin.H responses by hand.
+var (
+	InvalidParameter   = &APIError{Code: "INVALID_PARAMETER", HTTPStatus: http.StatusBadRequest, Message: "invalid request parameter"}
+	Unauthorized       = &APIError{Code: "UNAUTHORIZED", HTTPStatus: http.StatusUnauthorized, Message: "unauthorized"}
+	Forbidden          = &APIError{Code: "FORBIDDEN", HTTPStatus: http.StatusForbidden, Message: "forbidden"}
+	InsufficientCredit = &APIError{Code: "INSUFFICIENT_CREDIT", HTTPStatus: http.StatusBadRequest, Message: "insufficient credit"}
+	UserNotFound       = &APIError{Code: "USER_NOT_FOUND", HTTPStatus: http.StatusNotFound, Message: "user not found"}
+	NotFound           = &APIError{Code: "NOT_FOUND", HTTPStatus: http.StatusNotFound, Message: "resource not found"}
+	Conflict           = &APIError{Code: "CONFLICT", HTTPStatus: http.StatusConflict, Message: "resource already exists"}
+	DBError            = &APIError{Code: "DB_ERROR", HTTPStatus: http.StatusInternalServerError, Message: "database error"}
+	Internal           = &APIError{Code: "INTERNAL", HTTPStatus: http.StatusInternalServerError, Message: "internal server error"}
+)
@@ -0,0 +1,24 @@
+package apierror
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FromBindError normalizes an error returned by c.ShouldBindJSON into an
+// InvalidParameter APIError, expanding validator.ValidationErrors into
+// one detail entry per offending field.
+func FromBindError(err error) *APIError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		details := make(map[string]string, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			details[fieldErr.Field()] = fmt.Sprintf("failed on the %q tag", fieldErr.Tag())
+		}
+		return InvalidParameter.With(details)
+	}
+
+	return InvalidParameter.With(err.Error())
+}
@@ -0,0 +1,40 @@
+package apierror
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// Middleware renders the last error attached via c.Error as the
+// {"code", "message", "details"} JSON envelope, with the matching HTTP
+// status. It must be registered before any handler that calls c.Error.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 {
+			return
+		}
+
+		apiErr := toAPIError(c.Errors.Last().Err)
+		c.JSON(apiErr.HTTPStatus, apiErr)
+	}
+}
+
+// toAPIError normalizes any error raised via c.Error into an *APIError,
+// translating validator.ValidationErrors into per-field details.
+func toAPIError(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return FromBindError(err)
+	}
+
+	return Internal.With(err.Error())
+}
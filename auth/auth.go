@@ -0,0 +1,190 @@
+// Package auth issues and validates the JWT access tokens and opaque
+// refresh tokens used to authenticate API requests.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"time"
+
+	"gotestbackend/apierror"
+	"gotestbackend/database"
+	"gotestbackend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var (
+	// ErrInvalidToken is returned when a token fails signature, expiry, or
+	// lookup validation.
+	ErrInvalidToken = errors.New("invalid or expired token")
+)
+
+// Claims are the custom JWT claims carried by an access token.
+type Claims struct {
+	UserID uint `json:"user_id"`
+	jwt.RegisteredClaims
+}
+
+func accessSecret() []byte {
+	return []byte(os.Getenv("JWT_ACCESS_SECRET"))
+}
+
+func refreshSecret() []byte {
+	return []byte(os.Getenv("JWT_REFRESH_SECRET"))
+}
+
+// GenerateAccessToken issues a short-lived, HS256-signed access token for
+// the given user.
+func GenerateAccessToken(userID uint) (string, error) {
+	claims := Claims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(accessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(accessSecret())
+}
+
+// ParseAccessToken validates the signature and expiry of an access token
+// and returns its claims.
+func ParseAccessToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return accessSecret(), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func hashRefreshToken(plain string) string {
+	sum := sha256.Sum256(append([]byte(plain), refreshSecret()...))
+	return hex.EncodeToString(sum[:])
+}
+
+func newOpaqueToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// IssueRefreshToken creates a new opaque refresh token for the user and
+// persists its hash, returning the plaintext value to hand to the client.
+func IssueRefreshToken(userID uint) (string, error) {
+	plain, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	record := models.RefreshToken{
+		UserID:    userID,
+		TokenHash: hashRefreshToken(plain),
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+	}
+	if err := database.DB.Create(&record).Error; err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+// RotateRefreshToken validates a presented refresh token, deletes it, and
+// issues a fresh one for the same user. Returns the new refresh token
+// plaintext along with the user ID it belongs to.
+func RotateRefreshToken(plain string) (newPlain string, userID uint, err error) {
+	var record models.RefreshToken
+	err = database.DB.Where("token_hash = ?", hashRefreshToken(plain)).First(&record).Error
+	if err != nil {
+		return "", 0, ErrInvalidToken
+	}
+	if time.Now().After(record.ExpiresAt) {
+		database.DB.Delete(&record)
+		return "", 0, ErrInvalidToken
+	}
+
+	if err := database.DB.Delete(&record).Error; err != nil {
+		return "", 0, err
+	}
+
+	newPlain, err = IssueRefreshToken(record.UserID)
+	if err != nil {
+		return "", 0, err
+	}
+	return newPlain, record.UserID, nil
+}
+
+// RevokeRefreshToken deletes the stored refresh token, ending the session.
+func RevokeRefreshToken(plain string) error {
+	return database.DB.Where("token_hash = ?", hashRefreshToken(plain)).Delete(&models.RefreshToken{}).Error
+}
+
+// RequireAuth is Gin middleware that parses the Authorization header,
+// validates the access token, and populates "user_id" in the request
+// context for downstream handlers.
+func RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		const prefix = "Bearer "
+		if len(header) <= len(prefix) || header[:len(prefix)] != prefix {
+			c.Error(apierror.Unauthorized.WithMessage("missing or malformed Authorization header"))
+			c.Abort()
+			return
+		}
+
+		claims, err := ParseAccessToken(header[len(prefix):])
+		if err != nil {
+			c.Error(apierror.Unauthorized.WithMessage("invalid or expired token"))
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", claims.UserID)
+		c.Next()
+	}
+}
+
+// RequireRole is Gin middleware, applied after RequireAuth, that loads the
+// authenticated user's role and 403s the request unless it matches one of
+// the given roles.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.Error(apierror.Unauthorized.WithMessage("user not logged in"))
+			c.Abort()
+			return
+		}
+
+		var user models.User
+		if err := database.DB.Select("role").First(&user, userID).Error; err != nil {
+			c.Error(apierror.Unauthorized.WithMessage("user not found"))
+			c.Abort()
+			return
+		}
+
+		for _, role := range roles {
+			if user.Role == role {
+				c.Next()
+				return
+			}
+		}
+
+		c.Error(apierror.Forbidden)
+		c.Abort()
+	}
+}
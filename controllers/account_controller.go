@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"net/http"
+
+	"gotestbackend/apierror"
+	"gotestbackend/database"
+	"gotestbackend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetUserByAccount looks up a user by account number. Unlike a bare
+// DB.First(&user, account_number) - which GORM treats as a primary-key
+// lookup - this filters on the account_number column, and it propagates
+// gorm.ErrRecordNotFound instead of swallowing it behind a nil error.
+func GetUserByAccount(accountNumber string) (models.User, error) {
+	var user models.User
+	err := database.DB.Where("account_number = ?", accountNumber).First(&user).Error
+	return user, err
+}
+
+// requireAccountAccess 403s the request unless the authenticated caller
+// owns accountNumber or holds the admin role, the same check RequireRole
+// does for admin-only routes. Account numbers are guessable 10-digit
+// strings, so RequireAuth alone would let any user read any other
+// account's balance and ledger.
+func requireAccountAccess(c *gin.Context, accountNumber string) bool {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.Unauthorized.WithMessage("user not logged in"))
+		return false
+	}
+
+	var caller models.User
+	if err := database.DB.First(&caller, userID).Error; err != nil {
+		c.Error(apierror.Unauthorized.WithMessage("user not found"))
+		return false
+	}
+
+	if caller.Role == models.RoleAdmin || caller.AccountNumber == accountNumber {
+		return true
+	}
+
+	c.Error(apierror.Forbidden)
+	return false
+}
+
+// GetAccount returns the sanitized profile for the given account number.
+func GetAccount(c *gin.Context) {
+	number := c.Param("number")
+	if !requireAccountAccess(c, number) {
+		return
+	}
+
+	user, err := GetUserByAccount(number)
+	if err != nil {
+		c.Error(apierror.UserNotFound)
+		return
+	}
+	c.JSON(http.StatusOK, toUserDTO(user))
+}
+
+// GetAccountTransactions returns a paginated view of the ledger entries
+// where the given account is either the debit or credit side.
+func GetAccountTransactions(c *gin.Context) {
+	number := c.Param("number")
+	if !requireAccountAccess(c, number) {
+		return
+	}
+
+	user, err := GetUserByAccount(number)
+	if err != nil {
+		c.Error(apierror.UserNotFound)
+		return
+	}
+
+	page, pageSize := paginationParams(c)
+
+	ledgerQuery := func() *gorm.DB {
+		return database.DB.Model(&models.Transaction{}).
+			Where("debit_account_id = ? OR credit_account_id = ?", user.ID, user.ID)
+	}
+
+	var total int64
+	if err := ledgerQuery().Count(&total).Error; err != nil {
+		c.Error(apierror.DBError.With(err.Error()))
+		return
+	}
+
+	var transactions []models.Transaction
+	if err := ledgerQuery().Order("created_at DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&transactions).Error; err != nil {
+		c.Error(apierror.DBError.With(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":      transactions,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
@@ -0,0 +1,137 @@
+package oauth
+
+import (
+	"net/http"
+
+	"gotestbackend/apierror"
+	"gotestbackend/auth"
+	"gotestbackend/controllers"
+	"gotestbackend/database"
+	"gotestbackend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Login redirects the browser to the provider's consent screen, stashing a
+// signed anti-CSRF state value in a cookie.
+func Login(c *gin.Context) {
+	provider := c.Param("provider")
+	cfg, err := configFor(provider)
+	if err != nil {
+		c.Error(apierror.InvalidParameter.With(err.Error()))
+		return
+	}
+
+	state, cookieValue, err := newSignedState()
+	if err != nil {
+		c.Error(apierror.Internal.WithMessage("failed to start oauth flow"))
+		return
+	}
+
+	c.SetCookie(stateCookieName, cookieValue, 300, "/", "", false, true)
+	c.Redirect(http.StatusTemporaryRedirect, cfg.AuthCodeURL(state))
+}
+
+// Callback validates the state cookie, exchanges the code for a token,
+// fetches the provider profile, upserts the local user, and issues the
+// same JWT session that password login does.
+func Callback(c *gin.Context) {
+	provider := c.Param("provider")
+	cfg, err := configFor(provider)
+	if err != nil {
+		c.Error(apierror.InvalidParameter.With(err.Error()))
+		return
+	}
+
+	cookieValue, err := c.Cookie(stateCookieName)
+	if err != nil {
+		c.Error(apierror.InvalidParameter.WithMessage("missing oauth state cookie"))
+		return
+	}
+	c.SetCookie(stateCookieName, "", -1, "/", "", false, true)
+
+	if err := verifyState(c.Query("state"), cookieValue); err != nil {
+		c.Error(apierror.InvalidParameter.WithMessage("invalid oauth state"))
+		return
+	}
+
+	token, err := cfg.Exchange(c.Request.Context(), c.Query("code"))
+	if err != nil {
+		c.Error(apierror.Unauthorized.WithMessage("failed to exchange oauth code"))
+		return
+	}
+
+	profile, err := fetchProfile(c.Request.Context(), provider, cfg, token)
+	if err != nil {
+		c.Error(apierror.Internal.WithMessage("failed to fetch oauth profile"))
+		return
+	}
+
+	user, err := upsertUser(provider, profile)
+	if err != nil {
+		c.Error(apierror.DBError.WithMessage("failed to link oauth account"))
+		return
+	}
+
+	accessToken, err := auth.GenerateAccessToken(user.ID)
+	if err != nil {
+		c.Error(apierror.Internal.WithMessage("failed to issue access token"))
+		return
+	}
+
+	refreshToken, err := auth.IssueRefreshToken(user.ID)
+	if err != nil {
+		c.Error(apierror.Internal.WithMessage("failed to issue refresh token"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token":  accessToken,
+		"refresh_token": refreshToken,
+	})
+}
+
+// upsertUser links the oauth identity to an existing user with the same
+// verified email, or creates a new one. Linking requires profile.Email to
+// be both non-empty and verified by the provider: otherwise an empty
+// email would match every other unlinked account by the same blank-email
+// lookup, and an unverified email would let anyone claim an existing
+// account just by registering it with an identity provider.
+func upsertUser(provider string, profile *Profile) (*models.User, error) {
+	var user models.User
+
+	err := database.DB.Where("oauth_provider = ? AND oauth_subject = ?", provider, profile.Subject).First(&user).Error
+	if err == nil {
+		return &user, nil
+	}
+
+	if profile.Email != "" && profile.EmailVerified {
+		err = database.DB.Where("email = ?", profile.Email).First(&user).Error
+		if err == nil {
+			user.OAuthProvider = provider
+			user.OAuthSubject = profile.Subject
+			if err := database.DB.Save(&user).Error; err != nil {
+				return nil, err
+			}
+			return &user, nil
+		}
+	}
+
+	accountNumber, err := controllers.GenerateAccountNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	user = models.User{
+		Email:         profile.Email,
+		Username:      profile.Email,
+		AccountNumber: accountNumber,
+		OAuthProvider: provider,
+		OAuthSubject:  profile.Subject,
+		Credit:        1000.0,
+	}
+	if err := database.DB.Create(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,109 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+func fetchProfile(ctx context.Context, provider string, cfg *oauth2.Config, token *oauth2.Token) (*Profile, error) {
+	client := cfg.Client(ctx, token)
+
+	switch provider {
+	case "google":
+		return fetchGoogleProfile(client)
+	case "github":
+		return fetchGitHubProfile(client)
+	default:
+		return nil, &ErrUnknownProvider{Provider: provider}
+	}
+}
+
+func fetchGoogleProfile(client *http.Client) (*Profile, error) {
+	resp, err := client.Get("https://www.googleapis.com/oauth2/v3/userinfo")
+	if err != nil {
+		return nil, fmt.Errorf("fetch google profile: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch google profile: unexpected status %s", resp.Status)
+	}
+
+	var body struct {
+		Sub           string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode google profile: %w", err)
+	}
+	return &Profile{Subject: body.Sub, Email: body.Email, EmailVerified: body.EmailVerified}, nil
+}
+
+func fetchGitHubProfile(client *http.Client) (*Profile, error) {
+	resp, err := client.Get("https://api.github.com/user")
+	if err != nil {
+		return nil, fmt.Errorf("fetch github profile: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch github profile: unexpected status %s", resp.Status)
+	}
+
+	var body struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode github profile: %w", err)
+	}
+
+	email, verified, err := fetchGitHubPrimaryEmail(client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Profile{Subject: fmt.Sprintf("%d", body.ID), Email: email, EmailVerified: verified}, nil
+}
+
+// fetchGitHubPrimaryEmail looks up the verified primary address from
+// /user/emails, falling back to any other verified address when the
+// primary one is not verified, and returns "" when none is verified. GET /user's "email" field is empty for any account that
+// hasn't made its email public, even with the user:email scope granted,
+// so relying on it would leave most GitHub logins with no email at all.
+func fetchGitHubPrimaryEmail(client *http.Client) (email string, verified bool, err error) {
+	resp, err := client.Get("https://api.github.com/user/emails")
+	if err != nil {
+		return "", false, fmt.Errorf("fetch github emails: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", false, fmt.Errorf("fetch github emails: unexpected status %s", resp.Status)
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&emails); err != nil {
+		return "", false, fmt.Errorf("decode github emails: %w", err)
+	}
+
+	for _, e := range emails {
+		if e.Primary && e.Verified {
+			return e.Email, true, nil
+		}
+	}
+	for _, e := range emails {
+		if e.Verified {
+			return e.Email, true, nil
+		}
+	}
+	return "", false, nil
+}
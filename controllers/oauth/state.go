@@ -0,0 +1,45 @@
+package oauth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"os"
+)
+
+const stateCookieName = "oauth_state"
+
+var errInvalidState = errors.New("invalid oauth state")
+
+func stateSecret() []byte {
+	return []byte(os.Getenv("OAUTH_STATE_SECRET"))
+}
+
+// newSignedState generates a random state value and returns it alongside
+// an HMAC-signed cookie value binding it to this server.
+func newSignedState() (state, cookieValue string, err error) {
+	buf := make([]byte, 24)
+	if _, err = rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	state = base64.RawURLEncoding.EncodeToString(buf)
+	return state, sign(state), nil
+}
+
+func sign(state string) string {
+	mac := hmac.New(sha256.New, stateSecret())
+	mac.Write([]byte(state))
+	sig := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+	return state + "." + sig
+}
+
+// verifyState checks that the state returned by the provider matches the
+// signed value stored in the cookie.
+func verifyState(returnedState, cookieValue string) error {
+	if sign(returnedState) != cookieValue {
+		return errInvalidState
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"gotestbackend/apierror"
+	"gotestbackend/database"
+	"gotestbackend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errSelfTransfer       = apierror.InvalidParameter.WithMessage("sender and receiver account must differ")
+	errNonPositiveAmount  = apierror.InvalidParameter.WithMessage("amount must be positive")
+	errInsufficientCredit = apierror.InsufficientCredit
+	errAlreadyReversed    = apierror.InvalidParameter.WithMessage("only posted transactions can be reversed")
+	errNotSenderOwner     = apierror.Forbidden.WithMessage("sender account does not belong to the authenticated user")
+	errNotParticipant     = apierror.Forbidden.WithMessage("only a participant in the original transaction can reverse it")
+)
+
+// TransferPayload is used to bind a transfer request body.
+type TransferPayload struct {
+	SenderAccount   string  `json:"sender_account" binding:"required"`
+	ReceiverAccount string  `json:"receiver_account" binding:"required"`
+	Amount          float64 `json:"amount" binding:"required"`
+}
+
+// TransferCredit moves credit from one account to another and records a
+// single posted Transaction, all inside one DB transaction. Both account
+// rows are locked with SELECT ... FOR UPDATE in a deterministic order
+// (sorted by account number) so concurrent transfers can never deadlock.
+// The caller must be the owner of sender_account: otherwise any logged-in
+// user could drain an arbitrary account by naming it in the request body.
+func TransferCredit(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.Unauthorized.WithMessage("user not logged in"))
+		return
+	}
+
+	var payload TransferPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.Error(apierror.InvalidParameter.With(err.Error()))
+		return
+	}
+
+	if payload.SenderAccount == payload.ReceiverAccount {
+		c.Error(errSelfTransfer)
+		return
+	}
+	if payload.Amount <= 0 {
+		c.Error(errNonPositiveAmount)
+		return
+	}
+
+	var transaction models.Transaction
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		sender, receiver, err := lockAccountsInOrder(tx, payload.SenderAccount, payload.ReceiverAccount)
+		if err != nil {
+			return err
+		}
+
+		if sender.ID != userID {
+			return errNotSenderOwner
+		}
+
+		if sender.Credit < payload.Amount {
+			return errInsufficientCredit
+		}
+
+		sender.Credit -= payload.Amount
+		receiver.Credit += payload.Amount
+
+		if err := tx.Save(sender).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(receiver).Error; err != nil {
+			return err
+		}
+
+		transaction = models.Transaction{
+			DebitAccountID:  sender.ID,
+			CreditAccountID: receiver.ID,
+			Amount:          payload.Amount,
+			Status:          models.TransactionPosted,
+		}
+		return tx.Create(&transaction).Error
+	})
+
+	if err != nil {
+		c.Error(toTransferAPIError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// ReverseTransaction posts a compensating entry for a posted transaction,
+// swapping debit/credit accounts, and marks the original as reversed. Only
+// a debit or credit participant in the original transaction may reverse
+// it: RequireAuth alone would let any logged-in user reverse transfers
+// between two unrelated accounts.
+func ReverseTransaction(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apierror.Unauthorized.WithMessage("user not logged in"))
+		return
+	}
+
+	id := c.Param("id")
+
+	var reversal models.Transaction
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var original models.Transaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&original, id).Error; err != nil {
+			return err
+		}
+		if original.DebitAccountID != userID && original.CreditAccountID != userID {
+			return errNotParticipant
+		}
+		if original.Status != models.TransactionPosted {
+			return errAlreadyReversed
+		}
+
+		sender, receiver, err := lockAccountsByIDInOrder(tx, original.CreditAccountID, original.DebitAccountID)
+		if err != nil {
+			return err
+		}
+
+		sender.Credit -= original.Amount
+		receiver.Credit += original.Amount
+		if err := tx.Save(sender).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(receiver).Error; err != nil {
+			return err
+		}
+
+		reversal = models.Transaction{
+			DebitAccountID:  original.CreditAccountID,
+			CreditAccountID: original.DebitAccountID,
+			Amount:          original.Amount,
+			Status:          models.TransactionPosted,
+			ReversalOfID:    &original.ID,
+		}
+		if err := tx.Create(&reversal).Error; err != nil {
+			return err
+		}
+
+		original.Status = models.TransactionReversed
+		return tx.Save(&original).Error
+	})
+
+	if err != nil {
+		c.Error(toTransferAPIError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, reversal)
+}
+
+// lockAccountsInOrder locks the two account rows identified by account
+// number, always acquiring locks in ascending account-number order to
+// avoid deadlocking against a concurrent transfer between the same pair.
+func lockAccountsInOrder(tx *gorm.DB, accountA, accountB string) (a, b *models.User, err error) {
+	ordered := []string{accountA, accountB}
+	sort.Strings(ordered)
+
+	locked := make(map[string]*models.User, 2)
+	for _, account := range ordered {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("account_number = ?", account).First(&user).Error; err != nil {
+			return nil, nil, err
+		}
+		locked[account] = &user
+	}
+
+	return locked[accountA], locked[accountB], nil
+}
+
+// lockAccountsByIDInOrder is the reversal-path analogue of
+// lockAccountsInOrder, keyed by primary key instead of account number.
+func lockAccountsByIDInOrder(tx *gorm.DB, idA, idB uint) (a, b *models.User, err error) {
+	ordered := []uint{idA, idB}
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i] < ordered[j] })
+
+	locked := make(map[uint]*models.User, 2)
+	for _, id := range ordered {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, id).Error; err != nil {
+			return nil, nil, err
+		}
+		locked[id] = &user
+	}
+
+	return locked[idA], locked[idB], nil
+}
+
+// toTransferAPIError normalizes errors raised inside the transfer/reverse
+// DB transactions into an *apierror.APIError.
+func toTransferAPIError(err error) error {
+	var apiErr *apierror.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apierror.NotFound.WithMessage("account or transaction not found")
+	}
+	return apierror.DBError.With(err.Error())
+}
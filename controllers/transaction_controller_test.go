@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"gotestbackend/database"
+	"gotestbackend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestTransferCredit_ConcurrentTransfersConserveTotalCredit fires many
+// concurrent transfers around a ring of accounts and asserts that, once
+// they have all finished, the sum of credit across the ring is unchanged
+// and no account is negative, showing that lockAccountsInOrder's ordered
+// locking prevents lost updates under concurrency. It does not check how
+// many transfers succeeded. Requires a live Postgres
+// reachable via DATABASE_URL; skipped otherwise.
+func TestTransferCredit_ConcurrentTransfersConserveTotalCredit(t *testing.T) {
+	if os.Getenv("DATABASE_URL") == "" {
+		t.Skip("DATABASE_URL not set; skipping integration test")
+	}
+	if err := database.Connect(); err != nil {
+		t.Fatalf("connect database: %v", err)
+	}
+
+	const (
+		numAccounts    = 5
+		startingCredit = 1000.0
+		numTransfers   = 200
+		transferAmount = 10.0
+	)
+
+	accounts := make([]models.User, numAccounts)
+	for i := range accounts {
+		accounts[i] = models.User{
+			Username:      fmt.Sprintf("concurrency-test-%d-%d", os.Getpid(), i),
+			Email:         fmt.Sprintf("concurrency-test-%d-%d@example.com", os.Getpid(), i),
+			AccountNumber: fmt.Sprintf("9000000%03d", i),
+			Credit:        startingCredit,
+		}
+		if err := database.DB.Create(&accounts[i]).Error; err != nil {
+			t.Fatalf("create test account %d: %v", i, err)
+		}
+	}
+	defer func() {
+		for _, a := range accounts {
+			database.DB.Unscoped().Delete(&a)
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < numTransfers; i++ {
+		sender := accounts[i%numAccounts]
+		receiver := accounts[(i+1)%numAccounts]
+
+		wg.Add(1)
+		go func(senderID uint, senderAccount, receiverAccount string) {
+			defer wg.Done()
+
+			body, _ := json.Marshal(TransferPayload{
+				SenderAccount:   senderAccount,
+				ReceiverAccount: receiverAccount,
+				Amount:          transferAmount,
+			})
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/transactions/transfer", bytes.NewReader(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("user_id", senderID)
+
+			TransferCredit(c)
+		}(sender.ID, sender.AccountNumber, receiver.AccountNumber)
+	}
+	wg.Wait()
+
+	var totalAfter float64
+	for _, a := range accounts {
+		var fresh models.User
+		if err := database.DB.First(&fresh, a.ID).Error; err != nil {
+			t.Fatalf("reload account %d: %v", a.ID, err)
+		}
+		if fresh.Credit < 0 {
+			t.Errorf("account %d went negative: %v", a.ID, fresh.Credit)
+		}
+		totalAfter += fresh.Credit
+	}
+
+	wantTotal := startingCredit * numAccounts
+	if totalAfter != wantTotal {
+		t.Errorf("total credit not conserved: got %v, want %v", totalAfter, wantTotal)
+	}
+}
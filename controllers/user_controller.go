@@ -1,13 +1,21 @@
 package controllers
 
 import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
 	"net/http"
+	"strconv"
 
+	"gotestbackend/apierror"
+	"gotestbackend/auth"
 	"gotestbackend/database"
 	"gotestbackend/models"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // LoginPayload is used to bind login request body
@@ -16,6 +24,16 @@ type LoginPayload struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// RefreshPayload is used to bind refresh-token request body
+type RefreshPayload struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutPayload is used to bind logout request body
+type LogoutPayload struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
 // UpdateUserPayload is used to bind update request body
 type UpdateUserPayload struct {
 	FirstName     string `json:"first_name"`
@@ -24,40 +42,108 @@ type UpdateUserPayload struct {
 	AccountNumber string `json:"account_number"`
 }
 
-// Login handles user login
+// RegisterPayload is used to bind and validate a registration request.
+type RegisterPayload struct {
+	Username  string `json:"username" binding:"required"`
+	Email     string `json:"email" binding:"required,email"`
+	Password  string `json:"password" binding:"required,min=8"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// Login validates credentials and issues a JWT access token plus a
+// persisted, rotatable refresh token.
 func Login(c *gin.Context) {
 	var payload LoginPayload
 	var user models.User
 
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apierror.FromBindError(err))
 		return
 	}
 
 	if err := database.DB.Where("username = ?", payload.Username).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		c.Error(apierror.Unauthorized.WithMessage("invalid credentials"))
 		return
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(payload.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		c.Error(apierror.Unauthorized.WithMessage("invalid credentials"))
+		return
+	}
+
+	accessToken, err := auth.GenerateAccessToken(user.ID)
+	if err != nil {
+		c.Error(apierror.Internal.WithMessage("failed to issue access token"))
+		return
+	}
+
+	refreshToken, err := auth.IssueRefreshToken(user.ID)
+	if err != nil {
+		c.Error(apierror.Internal.WithMessage("failed to issue refresh token"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token":  accessToken,
+		"refresh_token": refreshToken,
+	})
+}
+
+// RefreshToken rotates a presented refresh token and issues a fresh
+// access/refresh token pair.
+func RefreshToken(c *gin.Context) {
+	var payload RefreshPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.Error(apierror.FromBindError(err))
+		return
+	}
+
+	newRefreshToken, userID, err := auth.RotateRefreshToken(payload.RefreshToken)
+	if err != nil {
+		c.Error(apierror.Unauthorized.WithMessage("invalid or expired refresh token"))
+		return
+	}
+
+	accessToken, err := auth.GenerateAccessToken(userID)
+	if err != nil {
+		c.Error(apierror.Internal.WithMessage("failed to issue access token"))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Login successful", "user_id": user.ID})
+	c.JSON(http.StatusOK, gin.H{
+		"access_token":  accessToken,
+		"refresh_token": newRefreshToken,
+	})
+}
+
+// Logout revokes the presented refresh token, ending the session.
+func Logout(c *gin.Context) {
+	var payload LogoutPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.Error(apierror.FromBindError(err))
+		return
+	}
+
+	if err := auth.RevokeRefreshToken(payload.RefreshToken); err != nil {
+		c.Error(apierror.Internal.WithMessage("failed to revoke refresh token"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logout successful"})
 }
 
 // GetUser retrieves the logged-in user's details
 func GetUserProfile(c *gin.Context) {
 	userId, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not logged in"})
+		c.Error(apierror.Unauthorized.WithMessage("user not logged in"))
 		return
 	}
 
 	var user models.User
 	if err := database.DB.First(&user, userId).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.Error(apierror.UserNotFound)
 		return
 	}
 
@@ -68,19 +154,19 @@ func GetUserProfile(c *gin.Context) {
 func UpdateUser(c *gin.Context) {
 	userId, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not logged in"})
+		c.Error(apierror.Unauthorized.WithMessage("user not logged in"))
 		return
 	}
 
 	var payload UpdateUserPayload
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apierror.FromBindError(err))
 		return
 	}
 
 	var user models.User
 	if err := database.DB.First(&user, userId).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.Error(apierror.UserNotFound)
 		return
 	}
 
@@ -98,141 +184,189 @@ func UpdateUser(c *gin.Context) {
 		user.Password = string(hashedPassword)
 	}
 
-	database.DB.Save(&user)
+	if err := database.DB.Save(&user).Error; err != nil {
+		c.Error(apierror.DBError.With(err.Error()))
+		return
+	}
 	c.JSON(http.StatusOK, user)
 }
 
+// Register validates and creates a new password-login account: duplicate
+// usernames/emails are rejected, the password is hashed only after
+// binding succeeds, and the account number is generated server-side.
 func Register(c *gin.Context) {
-	var newUser models.User
-
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(newUser.Password), bcrypt.DefaultCost)
-	newUser.Password = string(hashedPassword)
-
-	if err := c.ShouldBindJSON(&newUser); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var payload RegisterPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.Error(apierror.FromBindError(err))
 		return
 	}
 
-	// Simulate credit addition
-	newUser.Credit = 1000.0
-
-	// Save user to database (assuming db is initialized in main.go)
-	database.DB.Create(&newUser)
-
-	c.JSON(http.StatusCreated, newUser)
-}
-
-// api/accounting.go
-// TransferCredit transfers credit from one user to another
-func TransferCredit(c *gin.Context) {
-	// Parse request body
-	var transferRequest struct {
-		SenderAccount   string  `json:"sender_account"`
-		ReceiverAccount string  `json:"receiver_account"`
-		Amount          float64 `json:"amount"`
-	}
-	if err := c.ShouldBindJSON(&transferRequest); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var existing models.User
+	err := database.DB.Where("username = ? OR email = ?", payload.Username, payload.Email).First(&existing).Error
+	if err == nil {
+		c.Error(apierror.Conflict.WithMessage("username or email already registered"))
+		return
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.Error(apierror.DBError.With(err.Error()))
 		return
 	}
 
-	// Implement transfer logic
-	// Check if sender and receiver IDs are valid
-	sender, err := GetUserByAccount(transferRequest.SenderAccount)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Sender not found"})
+		c.Error(apierror.Internal.WithMessage("failed to hash password"))
 		return
 	}
 
-	receiver, err := GetUserByAccount(transferRequest.ReceiverAccount)
+	accountNumber, err := GenerateAccountNumber()
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Receiver not found"})
+		c.Error(apierror.Internal.WithMessage("failed to generate account number"))
 		return
 	}
 
-	// Update sender and receiver credits in database
-	// Validate if sender has enough credit
-	if sender.Credit < transferRequest.Amount {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient credit"})
+	newUser := models.User{
+		Username:      payload.Username,
+		Email:         payload.Email,
+		Password:      string(hashedPassword),
+		FirstName:     payload.FirstName,
+		LastName:      payload.LastName,
+		AccountNumber: accountNumber,
+		Credit:        1000.0,
+		Role:          models.RoleUser,
+	}
+	if err := database.DB.Create(&newUser).Error; err != nil {
+		c.Error(apierror.DBError.With(err.Error()))
 		return
 	}
 
-	// Perform credit transfer
-	// db.Model(&sender).Update("credit", sender.Credit - amount)
-	sender.Credit -= transferRequest.Amount
-	// db.Model(&receiver).Update("credit", receiver.Credit + amount)
-	receiver.Credit += transferRequest.Amount
+	c.JSON(http.StatusCreated, newUser)
+}
 
-	// Update sender and receiver in database
-	err = database.DB.Save(&sender).Error
+// GenerateAccountNumber produces a random 10-digit account number. Callers
+// rely on the unique index on users.account_number to catch the rare
+// collision.
+func GenerateAccountNumber() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(10_000_000_000))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update sender"})
-		return
+		return "", err
 	}
+	return fmt.Sprintf("%010d", n.Int64()), nil
+}
 
-	err = database.DB.Save(&receiver).Error
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update receiver"})
-		return
+// UserDTO is the sanitized representation of a User returned to clients.
+type UserDTO struct {
+	ID            uint    `json:"id"`
+	Username      string  `json:"username"`
+	Email         string  `json:"email"`
+	FirstName     string  `json:"first_name"`
+	LastName      string  `json:"last_name"`
+	AccountNumber string  `json:"account_number"`
+	Credit        float64 `json:"credit"`
+	Role          string  `json:"role"`
+}
+
+func toUserDTO(u models.User) UserDTO {
+	return UserDTO{
+		ID:            u.ID,
+		Username:      u.Username,
+		Email:         u.Email,
+		FirstName:     u.FirstName,
+		LastName:      u.LastName,
+		AccountNumber: u.AccountNumber,
+		Credit:        u.Credit,
+		Role:          u.Role,
 	}
+}
 
-	// Record transaction
-	transaction := models.Transaction{
-		SenderID:   sender.ID,
-		ReceiverID: receiver.ID,
-		Amount:     transferRequest.Amount,
+// paginationParams reads the ?page=&page_size= query params. A missing,
+// unparsable or out-of-range value (page below 1, page_size outside
+// 1..100) is replaced by its default: page 1, page_size 20.
+func paginationParams(c *gin.Context) (page, pageSize int) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
 	}
-	err = database.DB.Create(&transaction).Error
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record transaction"})
-		return
+	pageSize, err = strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		pageSize = 20
 	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Transfer successful"})
+	return page, pageSize
 }
 
+// GetAllUser returns a paginated, sanitized list of every user.
 func GetAllUser(c *gin.Context) {
-	var user models.User
-	if err := database.DB.Find(&user).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "All record not found"})
+	page, pageSize := paginationParams(c)
+
+	var total int64
+	if err := database.DB.Model(&models.User{}).Count(&total).Error; err != nil {
+		c.Error(apierror.DBError.With(err.Error()))
 		return
 	}
-	c.JSON(http.StatusOK, user)
+
+	var users []models.User
+	if err := database.DB.Offset((page - 1) * pageSize).Limit(pageSize).Find(&users).Error; err != nil {
+		c.Error(apierror.DBError.With(err.Error()))
+		return
+	}
+
+	dtos := make([]UserDTO, len(users))
+	for i, u := range users {
+		dtos[i] = toUserDTO(u)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":      dtos,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
 }
 func GetUserByID(c *gin.Context) {
 	id := c.Param("id")
 	var user models.User
 	if err := database.DB.First(&user, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.Error(apierror.UserNotFound)
 		return
 	}
 	c.JSON(http.StatusOK, user)
 }
-func GetUserByAccount(account_number string) (models.User, error) {
-	var user models.User
-	if err := database.DB.First(&user, account_number).Error; err != nil {
-		return user, nil
-	}
-	return user, nil
-}
 
+// UpdateUserByID lets an admin update another user's profile fields. Like
+// UpdateUser, it binds to a whitelisted payload and hashes any password
+// change rather than trusting a client-supplied models.User wholesale,
+// which would let a caller also overwrite credit, role, or the password
+// hash directly.
 func UpdateUserByID(c *gin.Context) {
 	id := c.Param("id")
 	var user models.User
 	if err := database.DB.First(&user, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.Error(apierror.UserNotFound)
 		return
 	}
 
-	var updatedUser models.User
-	if err := c.ShouldBindJSON(&updatedUser); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var payload UpdateUserPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.Error(apierror.FromBindError(err))
 		return
 	}
 
-	// Update user fields
-	database.DB.Model(&user).Updates(updatedUser)
+	if payload.FirstName != "" {
+		user.FirstName = payload.FirstName
+	}
+	if payload.LastName != "" {
+		user.LastName = payload.LastName
+	}
+	if payload.AccountNumber != "" {
+		user.AccountNumber = payload.AccountNumber
+	}
+	if payload.Password != "" {
+		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
+		user.Password = string(hashedPassword)
+	}
 
+	if err := database.DB.Save(&user).Error; err != nil {
+		c.Error(apierror.DBError.With(err.Error()))
+		return
+	}
 	c.JSON(http.StatusOK, user)
 }
 
@@ -240,7 +374,7 @@ func DeleteUserByID(c *gin.Context) {
 	id := c.Param("id")
 	var user models.User
 	if err := database.DB.First(&user, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.Error(apierror.UserNotFound)
 		return
 	}
 
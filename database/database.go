@@ -0,0 +1,35 @@
+package database
+
+import (
+	"fmt"
+	"os"
+
+	"gotestbackend/models"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// DB is the process-wide GORM handle, initialized by Connect.
+var DB *gorm.DB
+
+// Connect opens the database connection using DATABASE_URL and runs the
+// schema migrations for all known models.
+func Connect() error {
+	dsn := os.Getenv("DATABASE_URL")
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return fmt.Errorf("connect database: %w", err)
+	}
+
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.Transaction{},
+		&models.RefreshToken{},
+	); err != nil {
+		return fmt.Errorf("migrate database: %w", err)
+	}
+
+	DB = db
+	return nil
+}
@@ -0,0 +1,30 @@
+// Command gotestbackend starts the HTTP API: it connects to the
+// database, runs migrations, wires up routes, and starts listening.
+package main
+
+import (
+	"log"
+	"os"
+
+	"gotestbackend/database"
+	"gotestbackend/routes"
+
+	"github.com/gin-gonic/gin"
+)
+
+func main() {
+	if err := database.Connect(); err != nil {
+		log.Fatalf("connect database: %v", err)
+	}
+
+	r := gin.Default()
+	routes.Register(r)
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	if err := r.Run(":" + port); err != nil {
+		log.Fatalf("server stopped: %v", err)
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshToken is a persisted, rotatable refresh token issued to a user
+// during login. Only the hash of the token value is stored; the plaintext
+// is handed to the client once and never written to the database.
+type RefreshToken struct {
+	gorm.Model
+	UserID    uint      `json:"user_id" gorm:"index;not null"`
+	TokenHash string    `json:"-" gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "gorm.io/gorm"
+
+// TransactionStatus is the lifecycle state of a Transaction.
+type TransactionStatus string
+
+const (
+	TransactionPending  TransactionStatus = "pending"
+	TransactionPosted   TransactionStatus = "posted"
+	TransactionReversed TransactionStatus = "reversed"
+)
+
+// Transaction is a double-entry ledger record: a single posting debiting
+// one account and crediting another by the same amount. A reversal is a
+// second Transaction with the accounts swapped, linked back via
+// ReversalOfID; the original row keeps its accounts and amount and only
+// its Status changes, from posted to reversed.
+type Transaction struct {
+	gorm.Model
+	DebitAccountID  uint              `json:"debit_account_id"`
+	CreditAccountID uint              `json:"credit_account_id"`
+	Amount          float64           `json:"amount"`
+	Status          TransactionStatus `json:"status" gorm:"default:posted"`
+	ReversalOfID    *uint             `json:"reversal_of_id,omitempty"`
+}
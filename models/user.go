@@ -0,0 +1,27 @@
+package models
+
+import "gorm.io/gorm"
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// User represents an account holder in the system.
+type User struct {
+	gorm.Model
+	Username      string  `json:"username" gorm:"uniqueIndex;not null"`
+	Email         string  `json:"email" gorm:"uniqueIndex"`
+	Password      string  `json:"-"`
+	FirstName     string  `json:"first_name"`
+	LastName      string  `json:"last_name"`
+	AccountNumber string  `json:"account_number" gorm:"uniqueIndex"`
+	Credit        float64 `json:"credit"`
+	Role          string  `json:"role" gorm:"default:user"`
+
+	// OAuthProvider/OAuthSubject identify a user that signed up or linked
+	// their account via an external identity provider (e.g. "google").
+	// Both are empty for password-only accounts.
+	OAuthProvider string `json:"-" gorm:"uniqueIndex:idx_oauth_identity,where:oauth_subject <> ''"`
+	OAuthSubject  string `json:"-" gorm:"uniqueIndex:idx_oauth_identity,where:oauth_subject <> ''"`
+}
@@ -0,0 +1,63 @@
+// Package routes wires HTTP endpoints to their controllers.
+package routes
+
+import (
+	"gotestbackend/apierror"
+	"gotestbackend/auth"
+	"gotestbackend/controllers"
+	"gotestbackend/controllers/oauth"
+	"gotestbackend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Register attaches all application routes, and the error-rendering
+// middleware they rely on, to the given engine.
+func Register(r *gin.Engine) {
+	r.Use(apierror.Middleware())
+
+	authGroup := r.Group("/auth")
+	{
+		authGroup.POST("/login", controllers.Login)
+		authGroup.POST("/refresh", controllers.RefreshToken)
+		authGroup.POST("/logout", controllers.Logout)
+	}
+
+	oauthGroup := r.Group("/oauth")
+	{
+		oauthGroup.GET("/:provider/login", oauth.Login)
+		oauthGroup.GET("/:provider/callback", oauth.Callback)
+	}
+
+	r.POST("/register", controllers.Register)
+
+	users := r.Group("/users")
+	users.Use(auth.RequireAuth())
+	{
+		users.GET("/me", controllers.GetUserProfile)
+		users.PUT("/me", controllers.UpdateUser)
+	}
+
+	adminUsers := r.Group("/users")
+	adminUsers.Use(auth.RequireAuth(), auth.RequireRole(models.RoleAdmin))
+	{
+		adminUsers.GET("", controllers.GetAllUser)
+		adminUsers.GET("/:id", controllers.GetUserByID)
+		adminUsers.PUT("/:id", controllers.UpdateUserByID)
+		adminUsers.DELETE("/:id", controllers.DeleteUserByID)
+	}
+
+	transactions := r.Group("/transactions")
+	transactions.Use(auth.RequireAuth())
+	{
+		transactions.POST("/transfer", controllers.TransferCredit)
+		transactions.POST("/:id/reverse", controllers.ReverseTransaction)
+	}
+
+	accounts := r.Group("/accounts")
+	accounts.Use(auth.RequireAuth())
+	{
+		accounts.GET("/:number", controllers.GetAccount)
+		accounts.GET("/:number/transactions", controllers.GetAccountTransactions)
+	}
+}